@@ -0,0 +1,167 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	authV1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+)
+
+// accessCheckWorkers bounds how many SelfSubjectAccessReviews RunAccessChecks
+// will have in flight at once, so a large batch of checks (e.g. a full
+// `linkerd check -o json` pass) doesn't hammer the API server with an
+// unbounded burst of requests.
+const accessCheckWorkers = 8
+
+// AccessCheckSpec describes a single authorization check to run as part of a
+// RunAccessChecks batch.
+type AccessCheckSpec struct {
+	Namespace   string
+	Verb        string
+	Group       string
+	Version     string
+	Resource    string
+	Subresource string
+	Name        string
+}
+
+// AccessCheckResult is the outcome of a single AccessCheckSpec. Unlike a
+// plain error, a slice of these can be rendered as a full RBAC matrix
+// (e.g. by `linkerd check -o json` or the dashboard) instead of collapsing
+// every check down to a single opaque message.
+type AccessCheckResult struct {
+	Namespace       string `json:"namespace"`
+	Verb            string `json:"verb"`
+	Group           string `json:"group"`
+	Resource        string `json:"resource"`
+	Subresource     string `json:"subresource,omitempty"`
+	Name            string `json:"name,omitempty"`
+	Allowed         bool   `json:"allowed"`
+	Reason          string `json:"reason,omitempty"`
+	EvaluationError string `json:"evaluationError,omitempty"`
+
+	// err holds the original error returned by the API server, e.g. a
+	// *kerrors.StatusError, when the SSAR call itself failed. It's kept
+	// unexported (and so excluded from JSON output) purely so that
+	// single-check callers like ClusterAccess can still return the actual
+	// typed error for callers to type-switch on (kerrors.IsForbidden, etc.),
+	// matching the behavior they had before RunAccessChecks existed.
+	err error
+}
+
+// MarshalAccessCheckResults renders a batch of AccessCheckResults as
+// indented JSON, for consumption by `linkerd check -o json`.
+func MarshalAccessCheckResults(results []AccessCheckResult) ([]byte, error) {
+	return json.MarshalIndent(results, "", "  ")
+}
+
+// RunAccessChecks executes a batch of SelfSubjectAccessReviews concurrently,
+// bounded by accessCheckWorkers, and returns one AccessCheckResult per spec
+// in the same order as specs. Unlike ResourceAuthz, a denied or failed check
+// does not short-circuit the batch: every spec is always evaluated, so
+// callers get the complete picture in one pass.
+func RunAccessChecks(ctx context.Context, k8sClient kubernetes.Interface, specs []AccessCheckSpec) ([]AccessCheckResult, error) {
+	results := make([]AccessCheckResult, len(specs))
+
+	sem := make(chan struct{}, accessCheckWorkers)
+	var wg sync.WaitGroup
+	for i, spec := range specs {
+		wg.Add(1)
+		go func(i int, spec AccessCheckSpec) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				results[i] = accessCheckResultForSpec(spec, false, "", ctx.Err())
+				return
+			}
+			defer func() { <-sem }()
+
+			results[i] = runAccessCheck(k8sClient, spec)
+		}(i, spec)
+	}
+	wg.Wait()
+
+	return results, nil
+}
+
+func runAccessCheck(k8sClient kubernetes.Interface, spec AccessCheckSpec) AccessCheckResult {
+	ssar := &authV1.SelfSubjectAccessReview{
+		Spec: authV1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authV1.ResourceAttributes{
+				Namespace:   spec.Namespace,
+				Verb:        spec.Verb,
+				Group:       spec.Group,
+				Version:     spec.Version,
+				Resource:    spec.Resource,
+				Subresource: spec.Subresource,
+				Name:        spec.Name,
+			},
+		},
+	}
+
+	result, err := k8sClient.
+		AuthorizationV1().
+		SelfSubjectAccessReviews().
+		Create(ssar)
+	if err != nil {
+		return accessCheckResultForSpec(spec, false, "", err)
+	}
+
+	return accessCheckResultForSpec(spec, result.Status.Allowed, result.Status.Reason, nil)
+}
+
+func accessCheckResultForSpec(spec AccessCheckSpec, allowed bool, reason string, evalErr error) AccessCheckResult {
+	r := AccessCheckResult{
+		Namespace:   spec.Namespace,
+		Verb:        spec.Verb,
+		Group:       spec.Group,
+		Resource:    spec.Resource,
+		Subresource: spec.Subresource,
+		Name:        spec.Name,
+		Allowed:     allowed,
+		Reason:      reason,
+		err:         evalErr,
+	}
+	if evalErr != nil {
+		r.EvaluationError = evalErr.Error()
+	}
+	return r
+}
+
+// singleAccessCheck runs spec through RunAccessChecks and translates the
+// lone result back into the plain error shape existing callers expect.
+func singleAccessCheck(k8sClient kubernetes.Interface, spec AccessCheckSpec) error {
+	results, err := RunAccessChecks(context.Background(), k8sClient, []AccessCheckSpec{spec})
+	if err != nil {
+		return err
+	}
+	return resultToError(results[0])
+}
+
+func resultToError(r AccessCheckResult) error {
+	// Prefer the original, typed error (e.g. a *kerrors.StatusError) over
+	// the stringified EvaluationError, so callers can still type-switch on
+	// it (kerrors.IsForbidden, IsNotFound, ...) as they could before the SSAR
+	// was routed through RunAccessChecks.
+	if r.err != nil {
+		return r.err
+	}
+	if r.Allowed {
+		return nil
+	}
+
+	gk := schema.GroupKind{
+		Group: r.Group,
+		Kind:  r.Resource,
+	}
+	if r.Reason != "" {
+		return fmt.Errorf("not authorized to access %s: %s", gk, r.Reason)
+	}
+	return fmt.Errorf("not authorized to access %s", gk)
+}
@@ -0,0 +1,84 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	authV1 "k8s.io/api/authorization/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestRunAccessChecks_Aggregates(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		ssar := action.(k8stesting.CreateAction).GetObject().(*authV1.SelfSubjectAccessReview)
+		ssar.Status = authV1.SubjectAccessReviewStatus{
+			Allowed: ssar.Spec.ResourceAttributes.Resource == "pods",
+			Reason:  "denied by test",
+		}
+		return true, ssar, nil
+	})
+
+	specs := []AccessCheckSpec{
+		{Verb: "list", Resource: "pods"},
+		{Verb: "list", Resource: "secrets"},
+	}
+	results, err := RunAccessChecks(context.Background(), client, specs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if !results[0].Allowed {
+		t.Errorf("expected the pods check to be allowed, got %+v", results[0])
+	}
+	if results[1].Allowed {
+		t.Errorf("expected the secrets check to be denied, got %+v", results[1])
+	}
+}
+
+func TestMarshalAccessCheckResults(t *testing.T) {
+	results := []AccessCheckResult{
+		{Namespace: "linkerd", Verb: "list", Resource: "pods", Allowed: true},
+		{Namespace: "linkerd", Verb: "create", Resource: "secrets", Allowed: false, Reason: "denied"},
+	}
+
+	out, err := MarshalAccessCheckResults(results)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var decoded []AccessCheckResult
+	if err := json.Unmarshal(out, &decoded); err != nil {
+		t.Fatalf("failed to round-trip marshaled output: %v", err)
+	}
+	if len(decoded) != 2 || decoded[0].Resource != "pods" || decoded[1].Reason != "denied" {
+		t.Errorf("unexpected round-tripped results: %+v", decoded)
+	}
+}
+
+func TestClusterAccess_PreservesTypedError(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, &kerrors.StatusError{ErrStatus: metav1.Status{
+			Status: metav1.StatusFailure,
+			Code:   http.StatusForbidden,
+			Reason: metav1.StatusReasonForbidden,
+		}}
+	})
+
+	err := ClusterAccess(client)
+	if err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+	if !kerrors.IsForbidden(err) {
+		t.Errorf("expected ClusterAccess to return a typed Forbidden error callers can type-switch on, got: %v (%T)", err, err)
+	}
+}
@@ -5,7 +5,7 @@ import (
 	"fmt"
 
 	authV1 "k8s.io/api/authorization/v1"
-	discovery "k8s.io/api/discovery/v1beta1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes"
@@ -73,6 +73,65 @@ func ResourceAuthzForUser(
 	return evaluateAccessReviewStatus(group, resource, result.Status)
 }
 
+// LocalResourceAuthzForUser checks whether a given user is authorized to
+// perform a given action within namespace, using a LocalSubjectAccessReview
+// rather than a cluster-scoped SubjectAccessReview. This matters in
+// multi-tenant clusters where the control plane's service account may only
+// be granted SAR permissions within specific namespaces, via a namespaced
+// RoleBinding, rather than cluster-wide.
+func LocalResourceAuthzForUser(
+	client kubernetes.Interface,
+	namespace, verb, group, version, resource, subresource, name, user string, userGroups []string) error {
+	sar := &authV1.LocalSubjectAccessReview{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+		},
+		Spec: authV1.SubjectAccessReviewSpec{
+			User:   user,
+			Groups: userGroups,
+			ResourceAttributes: &authV1.ResourceAttributes{
+				Namespace:   namespace,
+				Verb:        verb,
+				Group:       group,
+				Version:     version,
+				Resource:    resource,
+				Subresource: subresource,
+				Name:        name,
+			},
+		},
+	}
+
+	result, err := client.
+		AuthorizationV1().
+		LocalSubjectAccessReviews(namespace).
+		Create(sar)
+	if err != nil {
+		return err
+	}
+
+	return evaluateAccessReviewStatus(group, resource, result.Status)
+}
+
+// ResourceAuthzForUserAuto checks whether a given user is authorized to
+// perform a given action, trying a cluster-scoped SubjectAccessReview first
+// and falling back to a namespace-scoped LocalSubjectAccessReview if that is
+// forbidden. This accommodates clusters where the caller's service account
+// can only create SARs within namespace, not cluster-wide.
+func ResourceAuthzForUserAuto(
+	client kubernetes.Interface,
+	namespace, verb, group, version, resource, subresource, name, user string, userGroups []string) error {
+	err := ResourceAuthzForUser(client, namespace, verb, group, version, resource, subresource, name, user, userGroups)
+	if err == nil {
+		return nil
+	}
+
+	if !kerrors.IsForbidden(err) {
+		return err
+	}
+
+	return LocalResourceAuthzForUser(client, namespace, verb, group, version, resource, subresource, name, user, userGroups)
+}
+
 func evaluateAccessReviewStatus(group, resource string, status authV1.SubjectAccessReviewStatus) error {
 	if status.Allowed {
 		return nil
@@ -88,79 +147,44 @@ func evaluateAccessReviewStatus(group, resource string, status authV1.SubjectAcc
 	return fmt.Errorf("not authorized to access %s", gk)
 }
 
-// ServiceProfilesAccess checks whether the ServiceProfile CRD is installed
-// on the cluster and the client is authorized to access ServiceProfiles.
-func ServiceProfilesAccess(k8sClient kubernetes.Interface) error {
-	res, err := k8sClient.Discovery().ServerResourcesForGroupVersion(ServiceProfileAPIVersion)
-	if err != nil {
-		return err
-	}
-
-	if res.GroupVersion == ServiceProfileAPIVersion {
-		for _, apiRes := range res.APIResources {
-			if apiRes.Kind == ServiceProfileKind {
-				return ResourceAuthz(k8sClient, "", "list", "linkerd.io", "", "serviceprofiles", "")
-			}
-		}
-	}
-
-	return errors.New("ServiceProfile CRD not found")
+// ClusterAccess verifies whether k8sClient is authorized to access all pods
+// in all namespaces in the cluster.
+func ClusterAccess(k8sClient kubernetes.Interface) error {
+	return singleAccessCheck(k8sClient, AccessCheckSpec{Verb: "list", Resource: "pods"})
 }
 
 // EndpointSliceAccess verifies whether the K8s cluster has
-// access to EndpointSlice resources.
+// access to EndpointSlice resources, negotiating discovery.k8s.io/v1 vs the
+// deprecated v1beta1 at runtime so it keeps working on clusters where
+// v1beta1 has been removed (Kubernetes 1.25+).
+//
+// Authorization is not checked separately via an SSAR: the List call in
+// checkEndpointSlicesExist already fails with a 403 if the client lacks
+// permission, so a dedicated preflight check would just be a redundant
+// round trip.
 func EndpointSliceAccess(k8sClient kubernetes.Interface) error {
-	gv := discovery.SchemeGroupVersion.String()
-	res, err := k8sClient.Discovery().ServerResourcesForGroupVersion(gv)
+	gv, err := NegotiateEndpointSliceVersion(k8sClient)
 	if err != nil {
 		return err
 	}
 
-	if res.GroupVersion == gv {
-		for _, apiRes := range res.APIResources {
-			if apiRes.Kind == "EndpointSlice" {
-				return checkEndpointSlicesExist(k8sClient)
-			}
-		}
-	}
-
-	return errors.New("EndpointSlice resource not found")
+	return checkEndpointSlicesExist(k8sClient, gv)
 }
 
-func checkEndpointSlicesExist(k8sClient kubernetes.Interface) error {
-	sliceList, err := k8sClient.DiscoveryV1beta1().EndpointSlices("").List(metav1.ListOptions{})
+func checkEndpointSlicesExist(k8sClient kubernetes.Interface, gv schema.GroupVersion) error {
+	lister, err := EndpointSliceListerFor(gv)
 	if err != nil {
 		return err
 	}
 
-	if len(sliceList.Items) > 0 {
-		return nil
-	}
-
-	return errors.New("no EndpointSlice resources exist in the cluster")
-}
-
-// LinkAccess checks whether the Link CRD is installed on the cluster and the
-// client is authorized to access Links.
-func LinkAccess(k8sClient kubernetes.Interface) error {
-	res, err := k8sClient.Discovery().ServerResourcesForGroupVersion(LinkAPIGroupVersion)
+	slices, err := lister.List(k8sClient)
 	if err != nil {
 		return err
 	}
 
-	if res.GroupVersion == LinkAPIGroupVersion {
-		for _, apiRes := range res.APIResources {
-			if apiRes.Kind == LinkKind {
-				return ResourceAuthz(k8sClient, "", "list", LinkAPIGroup, LinkAPIVersion, "links", "")
-			}
-		}
+	if len(slices) > 0 {
+		return nil
 	}
 
-	return errors.New("Link CRD not found")
-}
-
-// ClusterAccess verifies whether k8sClient is authorized to access all pods in
-// all namespaces in the cluster.
-func ClusterAccess(k8sClient kubernetes.Interface) error {
-	return ResourceAuthz(k8sClient, "", "list", "", "", "pods", "")
+	return errors.New("no EndpointSlice resources exist in the cluster")
 }
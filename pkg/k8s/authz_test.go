@@ -0,0 +1,85 @@
+package k8s
+
+import (
+	"net/http"
+	"testing"
+
+	authV1 "k8s.io/api/authorization/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func forbidSubjectAccessReviews(client *fake.Clientset) {
+	client.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, &kerrors.StatusError{ErrStatus: metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    http.StatusForbidden,
+			Reason:  metav1.StatusReasonForbidden,
+			Message: "SubjectAccessReviews are cluster-scoped only and this service account can't create them",
+		}}
+	})
+}
+
+func allowLocalSubjectAccessReviews(client *fake.Clientset) {
+	client.PrependReactor("create", "localsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		sar := action.(k8stesting.CreateAction).GetObject().(*authV1.LocalSubjectAccessReview)
+		sar.Status = authV1.SubjectAccessReviewStatus{Allowed: true}
+		return true, sar, nil
+	})
+}
+
+func TestLocalResourceAuthzForUser(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "localsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		sar := action.(k8stesting.CreateAction).GetObject().(*authV1.LocalSubjectAccessReview)
+		if sar.Namespace != "emojivoto" {
+			t.Errorf("expected LocalSubjectAccessReview to be created in namespace %q, got %q", "emojivoto", sar.Namespace)
+		}
+		sar.Status = authV1.SubjectAccessReviewStatus{Allowed: true}
+		return true, sar, nil
+	})
+
+	err := LocalResourceAuthzForUser(
+		client, "emojivoto", "get", "", "", "pods", "log", "", "user@example.com", nil,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestResourceAuthzForUserAuto_FallsBackOnForbidden(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	forbidSubjectAccessReviews(client)
+	allowLocalSubjectAccessReviews(client)
+
+	err := ResourceAuthzForUserAuto(
+		client, "emojivoto", "get", "", "", "pods", "log", "", "user@example.com", nil,
+	)
+	if err != nil {
+		t.Fatalf("expected the LocalSubjectAccessReview fallback to succeed, got: %v", err)
+	}
+}
+
+func TestResourceAuthzForUserAuto_DoesNotFallBackOnOtherErrors(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, &kerrors.StatusError{ErrStatus: metav1.Status{
+			Status: metav1.StatusFailure,
+			Code:   http.StatusInternalServerError,
+			Reason: metav1.StatusReasonInternalError,
+		}}
+	})
+	client.PrependReactor("create", "localsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		t.Fatal("LocalSubjectAccessReview should not be attempted for non-Forbidden errors")
+		return false, nil, nil
+	})
+
+	if err := ResourceAuthzForUserAuto(
+		client, "emojivoto", "get", "", "", "pods", "log", "", "user@example.com", nil,
+	); err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+}
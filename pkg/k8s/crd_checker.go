@@ -0,0 +1,208 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// PrerequisiteDenial describes a single (namespace, verb) combination that
+// was denied while checking prerequisites for a resource.
+type PrerequisiteDenial struct {
+	Namespace string
+	Verb      string
+	Resource  string
+	Reason    string
+
+	// Err is the original error behind this denial, e.g. a *kerrors.StatusError
+	// if the SSAR call itself failed rather than merely being denied. Callers
+	// can type-switch on it (kerrors.IsForbidden, IsNotFound, ...) the same
+	// way they could on the error returned by ResourceAuthz before batched
+	// prerequisite checks existed.
+	Err error
+}
+
+// ErrPrerequisitesFailed is returned by CRDChecker.CheckPrerequisites when one
+// or more of the requested (namespace, verb) checks were denied. Unlike a
+// plain error, it retains every denial so callers can report the full set of
+// missing RBAC permissions instead of just the first one encountered.
+type ErrPrerequisitesFailed struct {
+	Resource string
+	Denials  []PrerequisiteDenial
+}
+
+func (e *ErrPrerequisitesFailed) Error() string {
+	msgs := make([]string, 0, len(e.Denials))
+	for _, d := range e.Denials {
+		ns := d.Namespace
+		if ns == "" {
+			ns = "cluster-wide"
+		}
+		if d.Reason != "" {
+			msgs = append(msgs, fmt.Sprintf("not authorized to %s %s in %s: %s", d.Verb, d.Resource, ns, d.Reason))
+		} else {
+			msgs = append(msgs, fmt.Sprintf("not authorized to %s %s in %s", d.Verb, d.Resource, ns))
+		}
+	}
+	return fmt.Sprintf("missing prerequisites for %s:\n  %s", e.Resource, strings.Join(msgs, "\n  "))
+}
+
+// CRDChecker verifies that a CRD (or other API resource) is both served by
+// the cluster and accessible to the caller, across a batch of namespace/verb
+// combinations. It caches discovery results so that repeated calls against
+// the same group/version, e.g. across the many checks run by `linkerd check`,
+// don't each pay for a fresh ServerResourcesForGroupVersion round-trip.
+type CRDChecker struct {
+	k8sClient kubernetes.Interface
+
+	mu        sync.Mutex
+	discovery map[string]*v1.APIResourceList
+}
+
+// NewCRDChecker returns a CRDChecker backed by the given client.
+func NewCRDChecker(k8sClient kubernetes.Interface) *CRDChecker {
+	return &CRDChecker{
+		k8sClient: k8sClient,
+		discovery: make(map[string]*v1.APIResourceList),
+	}
+}
+
+// serverResourcesForGroupVersion returns the cached discovery result for
+// groupVersion, fetching and caching it on first use.
+func (c *CRDChecker) serverResourcesForGroupVersion(groupVersion string) (*v1.APIResourceList, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if res, ok := c.discovery[groupVersion]; ok {
+		return res, nil
+	}
+
+	res, err := c.k8sClient.Discovery().ServerResourcesForGroupVersion(groupVersion)
+	if err != nil {
+		return nil, err
+	}
+	c.discovery[groupVersion] = res
+	return res, nil
+}
+
+// CheckPrerequisites verifies that the resource identified by groupVersion
+// and resource is served by the cluster, and that the client is authorized
+// to perform every verb in verbs against every namespace it's keyed to, e.g.
+// verbs["" ] = []string{"list", "watch"} for a cluster-wide check and
+// verbs["linkerd"] = []string{"get", "create"} for a namespaced one.
+//
+// Every (namespace, verb) combination is checked via RunAccessChecks, so
+// SSARs are fired concurrently and a denial doesn't stop the rest of the
+// batch. Rather than stopping at the first denial, every denied combination
+// is collected and returned together as an *ErrPrerequisitesFailed, so
+// operators can fix all of their RBAC in one pass instead of a "fix one,
+// re-run, fix next" loop. The one exception is a batch of a single check
+// whose SSAR call itself failed: its original error is returned unwrapped.
+func (c *CRDChecker) CheckPrerequisites(
+	ctx context.Context,
+	namespaces []string,
+	verbs map[string][]string,
+	groupVersion, resource string,
+) error {
+	res, err := c.serverResourcesForGroupVersion(groupVersion)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, apiRes := range res.APIResources {
+		if apiRes.Name == resource {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("%s resource not found", resource)
+	}
+
+	group, version := groupVersionParts(groupVersion)
+
+	var specs []AccessCheckSpec
+	for _, ns := range namespaces {
+		for _, verb := range verbs[ns] {
+			specs = append(specs, AccessCheckSpec{
+				Namespace: ns,
+				Verb:      verb,
+				Group:     group,
+				Version:   version,
+				Resource:  resource,
+			})
+		}
+	}
+
+	results, err := RunAccessChecks(ctx, c.k8sClient, specs)
+	if err != nil {
+		return err
+	}
+
+	var denials []PrerequisiteDenial
+	for _, r := range results {
+		if r.Allowed {
+			continue
+		}
+		reason := r.Reason
+		if reason == "" {
+			reason = r.EvaluationError
+		}
+		denials = append(denials, PrerequisiteDenial{
+			Namespace: r.Namespace,
+			Verb:      r.Verb,
+			Resource:  r.Resource,
+			Reason:    reason,
+			Err:       r.err,
+		})
+	}
+
+	if len(denials) == 0 {
+		return nil
+	}
+
+	// A single-spec check (e.g. ServiceProfilesAccess/LinkAccess, which each
+	// check exactly one verb in one namespace) behaves just like
+	// singleAccessCheck: return the underlying typed error directly instead
+	// of wrapping it, so callers can still kerrors.IsForbidden/IsNotFound it.
+	if len(specs) == 1 && denials[0].Err != nil {
+		return denials[0].Err
+	}
+
+	return &ErrPrerequisitesFailed{Resource: resource, Denials: denials}
+}
+
+// groupVersionParts splits a "group/version" discovery string (or a bare
+// "version" for the core group) into its group and version components.
+func groupVersionParts(groupVersion string) (group, version string) {
+	parts := strings.SplitN(groupVersion, "/", 2)
+	if len(parts) == 1 {
+		return "", parts[0]
+	}
+	return parts[0], parts[1]
+}
+
+// ServiceProfilesAccess checks whether the ServiceProfile CRD is installed
+// on the cluster and the client is authorized to access ServiceProfiles.
+//
+// This is a method on CRDChecker, rather than a standalone function, so that
+// callers such as `linkerd check` can hold a single long-lived CRDChecker
+// across all of their checks and actually benefit from its discovery cache,
+// instead of a fresh instance being constructed and discarded per check.
+func (c *CRDChecker) ServiceProfilesAccess() error {
+	verbs := map[string][]string{"": {"list"}}
+	return c.CheckPrerequisites(context.Background(), []string{""}, verbs, ServiceProfileAPIVersion, "serviceprofiles")
+}
+
+// LinkAccess checks whether the Link CRD is installed on the cluster and the
+// client is authorized to access Links. See ServiceProfilesAccess for why
+// this is a CRDChecker method rather than a standalone function.
+func (c *CRDChecker) LinkAccess() error {
+	verbs := map[string][]string{"": {"list"}}
+	return c.CheckPrerequisites(context.Background(), []string{""}, verbs, LinkAPIGroupVersion, "links")
+}
@@ -0,0 +1,139 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	authV1 "k8s.io/api/authorization/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newFakeClientWithResources(resourceLists ...*metav1.APIResourceList) *fake.Clientset {
+	client := fake.NewSimpleClientset()
+	client.Fake.Resources = resourceLists
+	return client
+}
+
+// denySSARsFor makes the fake client deny SSARs for the given verbs, and
+// allow everything else.
+func denySSARsFor(client *fake.Clientset, deniedVerbs ...string) {
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		ssar := action.(k8stesting.CreateAction).GetObject().(*authV1.SelfSubjectAccessReview)
+		allowed := true
+		for _, v := range deniedVerbs {
+			if ssar.Spec.ResourceAttributes.Verb == v {
+				allowed = false
+				break
+			}
+		}
+		ssar.Status = authV1.SubjectAccessReviewStatus{Allowed: allowed, Reason: "denied by test"}
+		return true, ssar, nil
+	})
+}
+
+func TestCheckPrerequisites_AggregatesDenials(t *testing.T) {
+	client := newFakeClientWithResources(&metav1.APIResourceList{
+		GroupVersion: LinkAPIGroupVersion,
+		APIResources: []metav1.APIResource{{Name: "links", Kind: LinkKind}},
+	})
+	denySSARsFor(client, "create")
+
+	checker := NewCRDChecker(client)
+	verbs := map[string][]string{
+		"":        {"list", "create"},
+		"linkerd": {"get", "create"},
+	}
+	err := checker.CheckPrerequisites(context.Background(), []string{"", "linkerd"}, verbs, LinkAPIGroupVersion, "links")
+	if err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+
+	prereqErr, ok := err.(*ErrPrerequisitesFailed)
+	if !ok {
+		t.Fatalf("expected *ErrPrerequisitesFailed, got %T: %v", err, err)
+	}
+	if len(prereqErr.Denials) != 2 {
+		t.Fatalf("expected 2 denials (one per namespace), got %d: %+v", len(prereqErr.Denials), prereqErr.Denials)
+	}
+	for _, d := range prereqErr.Denials {
+		if d.Verb != "create" {
+			t.Errorf("expected only the 'create' verb to be denied, got denial for verb %q", d.Verb)
+		}
+	}
+	if !strings.Contains(prereqErr.Error(), "links") {
+		t.Errorf("expected error message to mention the resource, got: %s", prereqErr.Error())
+	}
+}
+
+func TestCheckPrerequisites_CachesDiscoveryAcrossInvocations(t *testing.T) {
+	client := newFakeClientWithResources(&metav1.APIResourceList{
+		GroupVersion: LinkAPIGroupVersion,
+		APIResources: []metav1.APIResource{{Name: "links", Kind: LinkKind}},
+	})
+	denySSARsFor(client)
+
+	discoveryCalls := 0
+	client.PrependReactor("get", "resource", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		discoveryCalls++
+		return false, nil, nil
+	})
+
+	checker := NewCRDChecker(client)
+	verbs := map[string][]string{"": {"list"}}
+
+	for i := 0; i < 3; i++ {
+		if err := checker.CheckPrerequisites(context.Background(), []string{""}, verbs, LinkAPIGroupVersion, "links"); err != nil {
+			t.Fatalf("unexpected error on call %d: %v", i, err)
+		}
+	}
+
+	if discoveryCalls != 1 {
+		t.Errorf("expected a single cached discovery call across 3 invocations, got %d", discoveryCalls)
+	}
+}
+
+func TestLinkAccess_PreservesTypedError(t *testing.T) {
+	client := newFakeClientWithResources(&metav1.APIResourceList{
+		GroupVersion: LinkAPIGroupVersion,
+		APIResources: []metav1.APIResource{{Name: "links", Kind: LinkKind}},
+	})
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, &kerrors.StatusError{ErrStatus: metav1.Status{
+			Status: metav1.StatusFailure,
+			Code:   http.StatusForbidden,
+			Reason: metav1.StatusReasonForbidden,
+		}}
+	})
+
+	checker := NewCRDChecker(client)
+	err := checker.LinkAccess()
+	if err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+	if !kerrors.IsForbidden(err) {
+		t.Errorf("expected LinkAccess to surface a typed Forbidden error callers can type-switch on, got: %v (%T)", err, err)
+	}
+}
+
+func TestCheckPrerequisites_ResourceNotFound(t *testing.T) {
+	client := newFakeClientWithResources(&metav1.APIResourceList{
+		GroupVersion: LinkAPIGroupVersion,
+		APIResources: []metav1.APIResource{},
+	})
+
+	checker := NewCRDChecker(client)
+	verbs := map[string][]string{"": {"list"}}
+	err := checker.CheckPrerequisites(context.Background(), []string{""}, verbs, LinkAPIGroupVersion, "links")
+	if err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+	if _, ok := err.(*ErrPrerequisitesFailed); ok {
+		t.Fatalf("expected a plain 'not found' error, not ErrPrerequisitesFailed: %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package k8s
+
+import (
+	"errors"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	discoveryv1beta1 "k8s.io/api/discovery/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+)
+
+// EndpointSliceLister abstracts over the version-specific EndpointSlice
+// clients so callers, such as the destination controller's endpoint
+// translator or `linkerd check`, can list EndpointSlices as a single
+// discovery.k8s.io/v1 type without caring whether the cluster actually
+// serves v1 or the deprecated v1beta1. This mirrors what upstream
+// Prometheus did when it dropped v1beta1 support.
+type EndpointSliceLister interface {
+	List(k8sClient kubernetes.Interface) ([]discoveryv1.EndpointSlice, error)
+}
+
+type v1EndpointSliceLister struct{}
+
+func (v1EndpointSliceLister) List(k8sClient kubernetes.Interface) ([]discoveryv1.EndpointSlice, error) {
+	sliceList, err := k8sClient.DiscoveryV1().EndpointSlices("").List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return sliceList.Items, nil
+}
+
+type v1beta1EndpointSliceLister struct{}
+
+func (v1beta1EndpointSliceLister) List(k8sClient kubernetes.Interface) ([]discoveryv1.EndpointSlice, error) {
+	sliceList, err := k8sClient.DiscoveryV1beta1().EndpointSlices("").List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	slices := make([]discoveryv1.EndpointSlice, 0, len(sliceList.Items))
+	for _, s := range sliceList.Items {
+		slices = append(slices, convertV1beta1EndpointSlice(s))
+	}
+	return slices, nil
+}
+
+// convertV1beta1EndpointSlice adapts a discovery.k8s.io/v1beta1 EndpointSlice
+// onto the discovery.k8s.io/v1 type, so that callers only ever have to deal
+// with one shape. It copies the slice's metadata, address type and ports, and
+// each endpoint's addresses, conditions, hostname, target ref and node name;
+// other endpoint fields, such as topology and hints, are left unset.
+func convertV1beta1EndpointSlice(s discoveryv1beta1.EndpointSlice) discoveryv1.EndpointSlice {
+	ports := make([]discoveryv1.EndpointPort, 0, len(s.Ports))
+	for _, p := range s.Ports {
+		ports = append(ports, discoveryv1.EndpointPort{
+			Name:        p.Name,
+			Protocol:    p.Protocol,
+			Port:        p.Port,
+			AppProtocol: p.AppProtocol,
+		})
+	}
+
+	endpoints := make([]discoveryv1.Endpoint, 0, len(s.Endpoints))
+	for _, e := range s.Endpoints {
+		endpoints = append(endpoints, discoveryv1.Endpoint{
+			Addresses: e.Addresses,
+			Conditions: discoveryv1.EndpointConditions{
+				Ready:       e.Conditions.Ready,
+				Serving:     e.Conditions.Serving,
+				Terminating: e.Conditions.Terminating,
+			},
+			Hostname:  e.Hostname,
+			TargetRef: e.TargetRef,
+			NodeName:  e.NodeName,
+		})
+	}
+
+	return discoveryv1.EndpointSlice{
+		ObjectMeta:  s.ObjectMeta,
+		AddressType: discoveryv1.AddressType(s.AddressType),
+		Endpoints:   endpoints,
+		Ports:       ports,
+	}
+}
+
+// NegotiateEndpointSliceVersion probes the cluster for the EndpointSlice API
+// version it actually serves, preferring discovery.k8s.io/v1 and falling
+// back to the deprecated v1beta1 only if v1 isn't available. v1beta1 was
+// removed in Kubernetes 1.25, so clusters running 1.25+ only serve v1.
+func NegotiateEndpointSliceVersion(k8sClient kubernetes.Interface) (schema.GroupVersion, error) {
+	v1gv := discoveryv1.SchemeGroupVersion
+	if res, err := k8sClient.Discovery().ServerResourcesForGroupVersion(v1gv.String()); err == nil {
+		if res.GroupVersion == v1gv.String() {
+			for _, apiRes := range res.APIResources {
+				if apiRes.Kind == "EndpointSlice" {
+					return v1gv, nil
+				}
+			}
+		}
+	}
+
+	v1beta1gv := discoveryv1beta1.SchemeGroupVersion
+	res, err := k8sClient.Discovery().ServerResourcesForGroupVersion(v1beta1gv.String())
+	if err != nil {
+		return schema.GroupVersion{}, err
+	}
+
+	if res.GroupVersion == v1beta1gv.String() {
+		for _, apiRes := range res.APIResources {
+			if apiRes.Kind == "EndpointSlice" {
+				return v1beta1gv, nil
+			}
+		}
+	}
+
+	return schema.GroupVersion{}, errors.New("EndpointSlice resource not found")
+}
+
+// EndpointSliceListerFor returns the EndpointSliceLister adaptor matching the
+// negotiated EndpointSlice group version, e.g. the one returned by
+// NegotiateEndpointSliceVersion. Downstream consumers that need to read
+// EndpointSlices, rather than just check for their existence, should use
+// this instead of reimplementing the v1/v1beta1 switch themselves.
+func EndpointSliceListerFor(gv schema.GroupVersion) (EndpointSliceLister, error) {
+	switch gv {
+	case discoveryv1.SchemeGroupVersion:
+		return v1EndpointSliceLister{}, nil
+	case discoveryv1beta1.SchemeGroupVersion:
+		return v1beta1EndpointSliceLister{}, nil
+	default:
+		return nil, errors.New("unsupported EndpointSlice group version: " + gv.String())
+	}
+}
@@ -0,0 +1,123 @@
+package k8s
+
+import (
+	"testing"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	discoveryv1beta1 "k8s.io/api/discovery/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNegotiateEndpointSliceVersion_PrefersV1(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.Fake.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: discoveryv1.SchemeGroupVersion.String(),
+			APIResources: []metav1.APIResource{{Name: "endpointslices", Kind: "EndpointSlice"}},
+		},
+		{
+			GroupVersion: discoveryv1beta1.SchemeGroupVersion.String(),
+			APIResources: []metav1.APIResource{{Name: "endpointslices", Kind: "EndpointSlice"}},
+		},
+	}
+
+	gv, err := NegotiateEndpointSliceVersion(client)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gv != discoveryv1.SchemeGroupVersion {
+		t.Errorf("expected negotiated version %s, got %s", discoveryv1.SchemeGroupVersion, gv)
+	}
+}
+
+func TestNegotiateEndpointSliceVersion_FallsBackToV1beta1(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.Fake.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: discoveryv1beta1.SchemeGroupVersion.String(),
+			APIResources: []metav1.APIResource{{Name: "endpointslices", Kind: "EndpointSlice"}},
+		},
+	}
+
+	gv, err := NegotiateEndpointSliceVersion(client)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gv != discoveryv1beta1.SchemeGroupVersion {
+		t.Errorf("expected negotiated version %s, got %s", discoveryv1beta1.SchemeGroupVersion, gv)
+	}
+}
+
+func TestNegotiateEndpointSliceVersion_NotFound(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.Fake.Resources = []*metav1.APIResourceList{}
+
+	if _, err := NegotiateEndpointSliceVersion(client); err == nil {
+		t.Fatal("expected an error when no EndpointSlice version is served, got nil")
+	}
+}
+
+func TestEndpointSliceListerFor(t *testing.T) {
+	if _, err := EndpointSliceListerFor(discoveryv1.SchemeGroupVersion); err != nil {
+		t.Errorf("expected a lister for %s, got error: %v", discoveryv1.SchemeGroupVersion, err)
+	}
+	if _, err := EndpointSliceListerFor(discoveryv1beta1.SchemeGroupVersion); err != nil {
+		t.Errorf("expected a lister for %s, got error: %v", discoveryv1beta1.SchemeGroupVersion, err)
+	}
+
+	bogus := schema.GroupVersion{Group: "bogus.example.com", Version: "v1"}
+	if _, err := EndpointSliceListerFor(bogus); err == nil {
+		t.Errorf("expected an error for unsupported group version %s, got nil", bogus)
+	}
+}
+
+func TestV1beta1EndpointSliceLister_ConvertsToV1(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	_, err := client.DiscoveryV1beta1().EndpointSlices("emojivoto").Create(&discoveryv1beta1.EndpointSlice{
+		ObjectMeta:  metav1.ObjectMeta{Name: "web-abc123", Namespace: "emojivoto"},
+		AddressType: discoveryv1beta1.AddressTypeIPv4,
+		Endpoints: []discoveryv1beta1.Endpoint{
+			{
+				Addresses:  []string{"10.0.0.1"},
+				Conditions: discoveryv1beta1.EndpointConditions{Ready: boolPtr(true)},
+				Hostname:   strPtr("web-0"),
+			},
+		},
+		Ports: []discoveryv1beta1.EndpointPort{
+			{Name: strPtr("http"), Port: int32Ptr(8080)},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to seed fake v1beta1 EndpointSlice: %v", err)
+	}
+
+	lister, err := EndpointSliceListerFor(discoveryv1beta1.SchemeGroupVersion)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	slices, err := lister.List(client)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(slices) != 1 {
+		t.Fatalf("expected 1 converted EndpointSlice, got %d", len(slices))
+	}
+
+	got := slices[0]
+	if got.Name != "web-abc123" {
+		t.Errorf("expected converted slice to keep name %q, got %q", "web-abc123", got.Name)
+	}
+	if len(got.Endpoints) != 1 || got.Endpoints[0].Addresses[0] != "10.0.0.1" {
+		t.Errorf("expected converted slice to carry over endpoint addresses, got %+v", got.Endpoints)
+	}
+	if len(got.Ports) != 1 || got.Ports[0].Name == nil || *got.Ports[0].Name != "http" {
+		t.Errorf("expected converted slice to carry over ports, got %+v", got.Ports)
+	}
+}
+
+func boolPtr(b bool) *bool    { return &b }
+func strPtr(s string) *string { return &s }
+func int32Ptr(i int32) *int32 { return &i }